@@ -20,7 +20,6 @@ import (
 	ctx "context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"strconv"
 	"syscall"
 	"time"
@@ -32,13 +31,44 @@ import (
 )
 
 type MDSDump struct {
-	Standbys    []MDSStandBy `json:"standbys"`
-	FileSystems []MDSMap     `json:"filesystems"`
+	Standbys    []MDSStandBy        `json:"standbys"`
+	FileSystems []MDSFilesystemDump `json:"filesystems"`
 }
 
+// MDSFilesystemDump is a single entry of the "filesystems" array returned by 'ceph fs dump',
+// pairing a filesystem's cluster ID (fscid) with its mds map.
+type MDSFilesystemDump struct {
+	ID     int    `json:"id"`
+	MDSMap MDSMap `json:"mdsmap"`
+}
+
+// MDSStandBy is a representation of a standby mds daemon as returned by 'ceph fs dump', enriched
+// with the daemon's 'ceph mds metadata' to authoritatively identify which filesystem it is
+// standing by for.
 type MDSStandBy struct {
 	Name string `json:"name"`
 	Rank int    `json:"rank"`
+	// GID is the daemon's global ID, as reported by 'ceph mds metadata'.
+	GID int
+	// StandbyForFSCID is the cluster ID (fscid) of the filesystem this daemon is a standby for,
+	// or -1 if the daemon is not constrained to a particular filesystem.
+	StandbyForFSCID int
+	// StandbyForName is the name of the filesystem this daemon is a standby for, if configured by
+	// name rather than by fscid.
+	StandbyForName string
+	// StandbyReplay is true if the daemon is in up:standby-replay for its target filesystem.
+	StandbyReplay bool
+}
+
+// MDSMetadata is a representation of a single entry returned by 'ceph mds metadata'.
+type MDSMetadata struct {
+	GID             int    `json:"gid"`
+	Name            string `json:"name"`
+	Rank            int    `json:"rank"`
+	FSClusterID     int    `json:"fs_cluster_id"`
+	StandbyForFSCID int    `json:"standby_for_fscid"`
+	StandbyForName  string `json:"standby_for_name"`
+	StandbyReplay   bool   `json:"standby_replay"`
 }
 
 // CephFilesystem is a representation of the json structure returned by 'ceph fs ls'
@@ -131,7 +161,7 @@ func AllowStandbyReplay(context *clusterd.Context, clusterInfo *ClusterInfo, fsN
 }
 
 // CreateFilesystem performs software configuration steps for Ceph to provide a new filesystem.
-func CreateFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name, metadataPool string, dataPools []string) error {
+func CreateFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name, metadataPool string, dataPools []DataPoolSpec) error {
 	if len(dataPools) == 0 {
 		return errors.New("at least one data pool is required")
 	}
@@ -147,7 +177,7 @@ func CreateFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name,
 	}
 
 	// create the filesystem
-	args = []string{"fs", "new", name, metadataPool, dataPools[0]}
+	args = []string{"fs", "new", name, metadataPool, dataPools[0].Name}
 
 	_, err = NewCephCommand(context, clusterInfo, args).Run()
 	if err != nil {
@@ -165,8 +195,22 @@ func CreateFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name,
 	return nil
 }
 
-// AddDataPoolToFilesystem associates the provided data pool with the filesystem.
-func AddDataPoolToFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name, poolName string) error {
+// AddDataPoolToFilesystem associates the provided data pool, and any of its
+// TopologyConstrainedPools, with the filesystem.
+func AddDataPoolToFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, name string, pool DataPoolSpec) error {
+	if err := addDataPool(context, clusterInfo, name, pool.Name); err != nil {
+		return err
+	}
+	for _, tp := range pool.TopologyConstrainedPools {
+		if err := addDataPool(context, clusterInfo, name, tp.PoolName); err != nil {
+			return errors.Wrapf(err, "failed to add topology-constrained pool %q (%s=%s)", tp.PoolName, tp.DomainLabel, tp.DomainValue)
+		}
+	}
+	return nil
+}
+
+// addDataPool associates a single pool with the filesystem.
+func addDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, name, poolName string) error {
 	args := []string{"fs", "add_data_pool", name, poolName}
 	_, err := NewCephCommand(context, clusterInfo, args).Run()
 	if err != nil {
@@ -364,12 +408,12 @@ func deleteFSPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolNames
 // WaitForNoStandbys waits for all standbys go away
 func WaitForNoStandbys(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, retryInterval, timeout time.Duration) error {
 	err := wait.PollUntilContextTimeout(clusterInfo.Context, retryInterval, timeout, true, func(ctx ctx.Context) (bool, error) {
-		mdsDump, err := GetMDSDump(context, clusterInfo)
+		standbys, err := GetStandbysForFilesystem(context, clusterInfo, fsName)
 		if err != nil {
-			logger.Errorf("failed to get fs dump. %v", err)
+			logger.Errorf("failed to get standbys for filesystem %q. %v", fsName, err)
 			return false, nil
 		}
-		return !filesystemHasStandby(mdsDump, fsName), nil
+		return len(standbys) == 0, nil
 	})
 	if err != nil {
 		return errors.Wrap(err, "timeout waiting for no standbys")
@@ -377,19 +421,71 @@ func WaitForNoStandbys(context *clusterd.Context, clusterInfo *ClusterInfo, fsNa
 	return nil
 }
 
-func filesystemHasStandby(dump *MDSDump, fsName string) bool {
+// GetStandbysForFilesystem returns the standby mds daemons associated with the given filesystem,
+// correlating 'ceph fs dump' standbys with 'ceph mds metadata' by daemon name. A standby is considered
+// associated with fsName if it is explicitly pinned to the filesystem's fscid or name; daemons
+// that are unconstrained standbys (available to any filesystem) are not included.
+func GetStandbysForFilesystem(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string) ([]MDSStandBy, error) {
+	dump, err := GetMDSDump(context, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fs dump")
+	}
+
+	fscid := -1
+	for _, fs := range dump.FileSystems {
+		if fs.MDSMap.FilesystemName == fsName {
+			fscid = fs.ID
+			break
+		}
+	}
+	if fscid == -1 {
+		// The filesystem isn't present in the dump (e.g. it has already been removed), so by
+		// definition it has no standbys.
+		return []MDSStandBy{}, nil
+	}
+
+	metadata, err := GetMdsMetadata(context, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get mds metadata")
+	}
+	metadataByName := make(map[string]MDSMetadata, len(metadata))
+	for _, m := range metadata {
+		metadataByName[m.Name] = m
+	}
+
+	var standbys []MDSStandBy
 	for _, standby := range dump.Standbys {
-		// The mds dump does not explicitly return the name of the filesystem that the
-		// daemon belongs to, so the matching to the filesystem name is based on the mds daemon name
-		// with a regular expression comparison with the expected suffix.
-		// For example, if the filesystem is "myfs", the standby name may be "myfs-a" or "myfs-b".
-		matchString := fmt.Sprintf("^%s-[a-z]{1}$", fsName)
-		matched, _ := regexp.MatchString(matchString, standby.Name)
-		if matched {
-			return true
+		// Default to "unconstrained" so a standby whose metadata can't be correlated is never
+		// mistaken for belonging to a filesystem whose fscid happens to be 0.
+		standby.StandbyForFSCID = -1
+		if m, ok := metadataByName[standby.Name]; ok {
+			standby.GID = m.GID
+			standby.StandbyForFSCID = m.StandbyForFSCID
+			standby.StandbyForName = m.StandbyForName
+			standby.StandbyReplay = m.StandbyReplay
 		}
+		if standby.StandbyForName == fsName || standby.StandbyForFSCID == fscid {
+			standbys = append(standbys, standby)
+		}
+	}
+	return standbys, nil
+}
+
+// GetMdsMetadata returns the metadata reported by every mds daemon known to the cluster,
+// including standbys, via 'ceph mds metadata'.
+func GetMdsMetadata(context *clusterd.Context, clusterInfo *ClusterInfo) ([]MDSMetadata, error) {
+	args := []string{"mds", "metadata"}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get mds metadata")
+	}
+
+	var metadata []MDSMetadata
+	if err := json.Unmarshal(buf, &metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mds metadata. %s", buf)
 	}
-	return false
+	return metadata, nil
 }
 
 func GetMDSDump(context *clusterd.Context, clusterInfo *ClusterInfo) (*MDSDump, error) {
@@ -0,0 +1,127 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+	"golang.org/x/sys/unix"
+)
+
+// TopologyPool names a CephFS data pool that is constrained to a Kubernetes topology domain, e.g.
+// a zone or region, so that PV provisioning can route volumes to the pool closest to the
+// workload. The pool itself (and its CRUSH rule restricting it to that domain) must already exist
+// before it is named here; this package only adds it to the filesystem. Data is routed into it by
+// setting a directory's layout with SetFileLayoutOnDirectory; PinSubvolumeGroup does not select a
+// data pool, it only controls which MDS rank(s) serve a subvolume group's metadata.
+type TopologyPool struct {
+	// PoolName is the name of the data pool to use for this topology domain.
+	PoolName string
+	// DomainLabel is the Kubernetes topology label this pool is constrained to, e.g.
+	// "topology.kubernetes.io/zone".
+	DomainLabel string
+	// DomainValue is the value of DomainLabel that this pool serves, e.g. "us-east-1a".
+	DomainValue string
+}
+
+// DataPoolSpec describes a CephFS data pool to add to a filesystem. The pool must already exist
+// with whatever CRUSH root/device-class/failure-domain the caller needs; creating or configuring
+// the underlying RADOS pool is out of scope for this package.
+type DataPoolSpec struct {
+	// Name of the data pool.
+	Name string
+	// TopologyConstrainedPools, if set, are additional data pools added alongside Name, each
+	// intended to serve a distinct Kubernetes topology domain.
+	TopologyConstrainedPools []TopologyPool
+}
+
+// FileLayout describes a CephFS file layout, the set of 'ceph.dir.layout.*' extended attributes
+// that control which pool new data in a directory tree is striped across.
+type FileLayout struct {
+	// PoolName is the data pool new file data should be written to.
+	PoolName string
+	// PoolNamespace further isolates data within PoolName, e.g. per-tenant.
+	PoolNamespace string
+	// StripeUnit is the size, in bytes, of each stripe. Zero leaves the layout default unchanged.
+	StripeUnit uint64
+	// StripeCount is the number of objects data is striped across. Zero leaves the layout
+	// default unchanged.
+	StripeCount uint64
+	// ObjectSize is the size, in bytes, of each backing RADOS object. Zero leaves the layout
+	// default unchanged.
+	ObjectSize uint64
+}
+
+func (l FileLayout) xattrs() map[string]string {
+	attrs := map[string]string{}
+	if l.PoolName != "" {
+		attrs["ceph.dir.layout.pool"] = l.PoolName
+	}
+	if l.PoolNamespace != "" {
+		attrs["ceph.dir.layout.pool_namespace"] = l.PoolNamespace
+	}
+	if l.StripeUnit != 0 {
+		attrs["ceph.dir.layout.stripe_unit"] = strconv.FormatUint(l.StripeUnit, 10)
+	}
+	if l.StripeCount != 0 {
+		attrs["ceph.dir.layout.stripe_count"] = strconv.FormatUint(l.StripeCount, 10)
+	}
+	if l.ObjectSize != 0 {
+		attrs["ceph.dir.layout.object_size"] = strconv.FormatUint(l.ObjectSize, 10)
+	}
+	return attrs
+}
+
+// SetFileLayoutOnDirectory applies a CephFS file layout to a directory on a mounted CephFS client,
+// via the same 'ceph.dir.layout.*' extended attributes that 'setfattr' would write. The path must
+// already be inside a mounted CephFS for filesystem fsName.
+func SetFileLayoutOnDirectory(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string, layout FileLayout) error {
+	logger.Infof("setting cephfs directory layout on %q for filesystem %q", path, fsName)
+	for name, value := range layout.xattrs() {
+		if err := unix.Setxattr(path, name, []byte(value), 0); err != nil {
+			return errors.Wrapf(err, "failed to set layout attribute %q to %q on %q for filesystem %q", name, value, path, fsName)
+		}
+	}
+	return nil
+}
+
+// PinType is a CephFS subvolumegroup export pin policy, controlling how the MDS distributes the
+// metadata for a subvolume group's tree across ranks.
+type PinType string
+
+const (
+	// PinTypeExport pins the subvolume group's tree to a single MDS rank.
+	PinTypeExport PinType = "export"
+	// PinTypeDistributed spreads the subvolume group's tree's subtrees across available ranks.
+	PinTypeDistributed PinType = "distributed"
+	// PinTypeRandom randomly exports fragments of the subvolume group's tree to other ranks.
+	PinTypeRandom PinType = "random"
+)
+
+// PinSubvolumeGroup pins a CephFS subvolume group to MDS rank(s) using the given pin policy and
+// value, e.g. PinTypeExport with value "0" to pin the group's tree to rank 0.
+func PinSubvolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, group string, pinType PinType, value string) error {
+	args := []string{"fs", "subvolumegroup", "pin", fsName, group, string(pinType), value}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to pin subvolumegroup %q in filesystem %q to %q %q", group, fsName, pinType, value)
+	}
+	return nil
+}
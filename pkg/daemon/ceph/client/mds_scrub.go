@@ -0,0 +1,190 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// ScrubOptions control how StartScrub walks the filesystem tree rooted at the scrub path.
+type ScrubOptions struct {
+	// Recursive scrubs the entire subtree rooted at path, rather than just path itself.
+	Recursive bool
+	// Repair attempts to automatically fix any damage the scrub finds.
+	Repair bool
+	// Force scrubs even if the path has already been scrubbed and has no pending changes.
+	Force bool
+	// Tag, if set, is used to group this scrub with other scrubs sharing the tag instead of
+	// having the MDS generate one.
+	Tag string
+}
+
+func (o ScrubOptions) flags() []string {
+	var flags []string
+	if o.Recursive {
+		flags = append(flags, "recursive")
+	}
+	if o.Repair {
+		flags = append(flags, "repair")
+	}
+	if o.Force {
+		flags = append(flags, "force")
+	}
+	return flags
+}
+
+// scrubOpsArg renders the scrub flags as the single comma-separated "scrubops" token that
+// 'ceph tell mds.<id> scrub start <path> <scrubops> <tag>' expects, e.g. "recursive,repair".
+func (o ScrubOptions) scrubOpsArg() string {
+	return strings.Join(o.flags(), ",")
+}
+
+// MDSScrubStatus reports which scrub tags are in progress or complete on an MDS rank.
+type MDSScrubStatus struct {
+	Status         string   `json:"status"`
+	InProgressTags []string `json:"in_progress_tags"`
+	CompleteTags   []string `json:"complete_tags"`
+}
+
+// MDSDamage is a single entry returned by 'ceph tell mds.<id> damage ls', describing a piece of
+// filesystem metadata the MDS has found to be corrupt.
+type MDSDamage struct {
+	ID       int    `json:"damage_id"`
+	Type     string `json:"damage_type"`
+	Path     string `json:"path"`
+	Ino      uint64 `json:"ino"`
+	Frag     string `json:"frag"`
+	Reported string `json:"reported_at"`
+}
+
+type scrubStartResponse struct {
+	ReturnCode int    `json:"return_code"`
+	ScrubTag   string `json:"scrub_tag"`
+	Mode       string `json:"mode"`
+}
+
+// tellMDS runs 'ceph tell mds.<id> <args...>' against the MDS currently serving the given rank.
+func tellMDS(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32, args ...string) ([]byte, error) {
+	id, err := GetMdsIdByRank(context, clusterInfo, fsName, rank)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve mds id for filesystem %q rank %d", fsName, rank)
+	}
+
+	tellArgs := append([]string{"tell", fmt.Sprintf("mds.%s", id)}, args...)
+	cmd := NewCephCommand(context, clusterInfo, tellArgs)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to tell mds.%s (filesystem %q rank %d) to run %v", id, fsName, rank, args)
+	}
+	return buf, nil
+}
+
+// StartScrub begins an online scrub of the given path on the MDS serving rank, returning the scrub
+// tag that can be used to track its progress via ScrubStatus.
+func StartScrub(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32, path string, opts ScrubOptions) (string, error) {
+	args := []string{"scrub", "start", path}
+	if scrubOps := opts.scrubOpsArg(); scrubOps != "" {
+		args = append(args, scrubOps)
+	}
+	if opts.Tag != "" {
+		args = append(args, opts.Tag)
+	}
+
+	buf, err := tellMDS(context, clusterInfo, fsName, rank, args...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to start scrub of path %q on filesystem %q rank %d", path, fsName, rank)
+	}
+
+	var resp scrubStartResponse
+	if err := json.Unmarshal(buf, &resp); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal scrub start response for filesystem %q rank %d", fsName, rank)
+	}
+	return resp.ScrubTag, nil
+}
+
+// ScrubStatus returns the current scrub status of the MDS serving the given rank.
+func ScrubStatus(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32) (*MDSScrubStatus, error) {
+	buf, err := tellMDS(context, clusterInfo, fsName, rank, "scrub", "status")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get scrub status for filesystem %q rank %d", fsName, rank)
+	}
+
+	var status MDSScrubStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal scrub status for filesystem %q rank %d", fsName, rank)
+	}
+	return &status, nil
+}
+
+// AbortScrub cancels any scrub(s) in progress on the MDS serving the given rank. If tag is
+// non-empty, only the scrub matching that tag is aborted.
+func AbortScrub(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32, tag string) error {
+	args := []string{"scrub", "abort"}
+	if tag != "" {
+		args = append(args, tag)
+	}
+	if _, err := tellMDS(context, clusterInfo, fsName, rank, args...); err != nil {
+		return errors.Wrapf(err, "failed to abort scrub for filesystem %q rank %d", fsName, rank)
+	}
+	return nil
+}
+
+// PauseScrub pauses all scrubs in progress on the MDS serving the given rank.
+func PauseScrub(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32) error {
+	if _, err := tellMDS(context, clusterInfo, fsName, rank, "scrub", "pause"); err != nil {
+		return errors.Wrapf(err, "failed to pause scrub for filesystem %q rank %d", fsName, rank)
+	}
+	return nil
+}
+
+// ResumeScrub resumes scrubs previously paused on the MDS serving the given rank.
+func ResumeScrub(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32) error {
+	if _, err := tellMDS(context, clusterInfo, fsName, rank, "scrub", "resume"); err != nil {
+		return errors.Wrapf(err, "failed to resume scrub for filesystem %q rank %d", fsName, rank)
+	}
+	return nil
+}
+
+// RepairDamage removes a previously reported damage entry from the MDS serving the given rank
+// after the underlying issue has been fixed (e.g. by a repair scrub).
+func RepairDamage(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32, damageID int) error {
+	if _, err := tellMDS(context, clusterInfo, fsName, rank, "damage", "rm", strconv.Itoa(damageID)); err != nil {
+		return errors.Wrapf(err, "failed to repair damage %d for filesystem %q rank %d", damageID, fsName, rank)
+	}
+	return nil
+}
+
+// ListDamage returns the damage table for the MDS serving the given rank.
+func ListDamage(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string, rank int32) ([]MDSDamage, error) {
+	buf, err := tellMDS(context, clusterInfo, fsName, rank, "damage", "ls")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list damage for filesystem %q rank %d", fsName, rank)
+	}
+
+	damage := []MDSDamage{}
+	if err := json.Unmarshal(buf, &damage); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal damage list for filesystem %q rank %d", fsName, rank)
+	}
+	return damage, nil
+}
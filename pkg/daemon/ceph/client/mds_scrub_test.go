@@ -0,0 +1,33 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubOptionsScrubOpsArg(t *testing.T) {
+	assert.Equal(t, "", ScrubOptions{}.scrubOpsArg())
+	assert.Equal(t, "recursive", ScrubOptions{Recursive: true}.scrubOpsArg())
+	// a combination of flags must be joined into a single comma-separated token; the MDS admin
+	// socket command treats each positional arg after <path> as scrubops then tag, so splitting
+	// flags across multiple args would silently drop flags and corrupt the tag.
+	assert.Equal(t, "recursive,repair", ScrubOptions{Recursive: true, Repair: true}.scrubOpsArg())
+	assert.Equal(t, "recursive,repair,force", ScrubOptions{Recursive: true, Repair: true, Force: true}.scrubOpsArg())
+}
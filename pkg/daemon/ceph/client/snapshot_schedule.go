@@ -0,0 +1,158 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// retentionPeriods is the fixed, documented ordering of CephFS snapshot retention periods: hourly,
+// daily, weekly, monthly, yearly, and by snapshot count.
+var retentionPeriods = []string{"h", "d", "w", "m", "y", "n"}
+
+// RetentionSpec maps a snapshot retention period (one of "h", "d", "w", "m", "y", "n") to the
+// number of snapshots of that period to keep.
+type RetentionSpec map[string]int
+
+// String renders the retention spec in the "<count><period>" concatenated form accepted by
+// 'ceph fs snap-schedule retention add', e.g. {"d": 7, "w": 4} becomes "7d4w".
+func (r RetentionSpec) String() string {
+	spec := ""
+	for _, period := range retentionPeriods {
+		if count, ok := r[period]; ok && count > 0 {
+			spec += strconv.Itoa(count) + period
+		}
+	}
+	return spec
+}
+
+// SnapshotSchedule is a representation of a single entry returned by 'ceph fs snap-schedule list'.
+type SnapshotSchedule struct {
+	Path      string         `json:"path"`
+	Schedule  string         `json:"schedule"`
+	Retention map[string]int `json:"retention"`
+	Start     string         `json:"start"`
+	Created   string         `json:"created"`
+	First     string         `json:"first"`
+	Last      string         `json:"last"`
+	Subvol    string         `json:"subvol"`
+	Group     string         `json:"group"`
+	Status    string         `json:"status"`
+}
+
+// EnableSnapshotScheduleModule ensures the ceph-mgr 'snap_schedule' module is enabled, which is
+// required before any snap-schedule command can be used.
+func EnableSnapshotScheduleModule(context *clusterd.Context, clusterInfo *ClusterInfo) error {
+	args := []string{"mgr", "module", "enable", "snap_schedule"}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrap(err, "failed to enable snap_schedule mgr module")
+	}
+	return nil
+}
+
+// AddSnapshotSchedule schedules periodic snapshots of path on fsName at the given interval (e.g.
+// "1h", "1d"), optionally starting at the given time (RFC3339). If start is empty, snapshots begin
+// immediately.
+func AddSnapshotSchedule(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path, schedule, start string) error {
+	args := []string{"fs", "snap-schedule", "add", path, schedule}
+	if start != "" {
+		args = append(args, start)
+	}
+	args = append(args, "--fs", fsName)
+
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to add snapshot schedule %q on path %q for filesystem %q", schedule, path, fsName)
+	}
+	return nil
+}
+
+// RemoveSnapshotSchedule removes a previously added snapshot schedule from path on fsName.
+func RemoveSnapshotSchedule(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path, schedule, start string) error {
+	args := []string{"fs", "snap-schedule", "remove", path}
+	if schedule != "" {
+		args = append(args, schedule)
+	}
+	if start != "" {
+		args = append(args, start)
+	}
+	args = append(args, "--fs", fsName)
+
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to remove snapshot schedule %q on path %q for filesystem %q", schedule, path, fsName)
+	}
+	return nil
+}
+
+// ListSnapshotSchedules lists the snapshot schedules configured on path for fsName. If recursive
+// is true, schedules configured on descendants of path are included too.
+func ListSnapshotSchedules(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string, recursive bool) ([]SnapshotSchedule, error) {
+	args := []string{"fs", "snap-schedule", "list", path, "--format", "json", "--fs", fsName}
+	if recursive {
+		args = append(args, "--recursive")
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list snapshot schedules on path %q for filesystem %q", path, fsName)
+	}
+
+	var schedules []SnapshotSchedule
+	if err := json.Unmarshal(buf, &schedules); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal snapshot schedule list for path %q filesystem %q", path, fsName)
+	}
+	return schedules, nil
+}
+
+// ActivateSnapshotSchedule (re)activates a previously deactivated snapshot schedule on path for
+// fsName.
+func ActivateSnapshotSchedule(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string) error {
+	args := []string{"fs", "snap-schedule", "activate", path, "--fs", fsName}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to activate snapshot schedule on path %q for filesystem %q", path, fsName)
+	}
+	return nil
+}
+
+// DeactivateSnapshotSchedule pauses a snapshot schedule on path for fsName without removing it.
+func DeactivateSnapshotSchedule(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string) error {
+	args := []string{"fs", "snap-schedule", "deactivate", path, "--fs", fsName}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to deactivate snapshot schedule on path %q for filesystem %q", path, fsName)
+	}
+	return nil
+}
+
+// AddSnapshotRetention configures how many snapshots to retain per period for the schedule(s) on
+// path for fsName.
+func AddSnapshotRetention(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string, spec RetentionSpec) error {
+	retention := spec.String()
+	if retention == "" {
+		return errors.New("retention spec must set at least one period")
+	}
+
+	args := []string{"fs", "snap-schedule", "retention", "add", path, retention, "--fs", fsName}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to add retention %q on path %q for filesystem %q", retention, path, fsName)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionSpecString(t *testing.T) {
+	assert.Equal(t, "", RetentionSpec{}.String())
+	assert.Equal(t, "7d", RetentionSpec{"d": 7}.String())
+	// periods must always render in the fixed h,d,w,m,y,n order regardless of map iteration order
+	assert.Equal(t, "4h7d4w12m3y10n", RetentionSpec{
+		"n": 10,
+		"y": 3,
+		"m": 12,
+		"w": 4,
+		"d": 7,
+		"h": 4,
+	}.String())
+	// zero and absent counts are both omitted
+	assert.Equal(t, "7d", RetentionSpec{"d": 7, "w": 0}.String())
+}
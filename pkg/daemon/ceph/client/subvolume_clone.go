@@ -0,0 +1,156 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	ctx "context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// CloneState represents the state of a CephFS subvolume clone operation.
+type CloneState string
+
+const (
+	// CloneStatePending indicates the clone has not started yet.
+	CloneStatePending CloneState = "pending"
+	// CloneStateInProgress indicates the clone is currently running.
+	CloneStateInProgress CloneState = "in-progress"
+	// CloneStateComplete indicates the clone finished successfully.
+	CloneStateComplete CloneState = "complete"
+	// CloneStateFailed indicates the clone failed.
+	CloneStateFailed CloneState = "failed"
+	// CloneStateCanceled indicates the clone was canceled before it completed.
+	CloneStateCanceled CloneState = "canceled"
+)
+
+// CloneOptions are the optional parameters accepted by CloneSubVolumeSnapshot.
+type CloneOptions struct {
+	// TargetGroup is the subvolume group the clone should be created in. If empty, the clone is
+	// created in the same group as the source subvolume.
+	TargetGroup string
+	// PoolLayout is the data pool layout to assign to the cloned subvolume.
+	PoolLayout string
+}
+
+// SubVolumeCloneStatus is a representation of the json structure returned by 'ceph fs clone status'.
+type SubVolumeCloneStatus struct {
+	State         CloneState           `json:"state"`
+	Source        SubVolumeCloneSource `json:"source"`
+	FailureReason string               `json:"failure"`
+}
+
+// SubVolumeCloneSource identifies the subvolume snapshot a clone was created from.
+type SubVolumeCloneSource struct {
+	Volume    string `json:"volume"`
+	Subvolume string `json:"subvolume"`
+	Snapshot  string `json:"snapshot"`
+	Group     string `json:"group"`
+}
+
+type subVolumeCloneStatusResponse struct {
+	Status SubVolumeCloneStatus `json:"status"`
+}
+
+// CloneSubVolumeSnapshot creates a new subvolume as a clone of the given subvolume snapshot.
+func CloneSubVolumeSnapshot(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, group, subvolume, snapshot, targetName string, opts *CloneOptions) error {
+	args := []string{"fs", "subvolume", "snapshot", "clone", fsName, subvolume, snapshot, targetName}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+	targetGroup := group
+	if opts != nil && opts.TargetGroup != "" {
+		targetGroup = opts.TargetGroup
+	}
+	if targetGroup != NoSubvolumeGroup {
+		args = append(args, "--target_group_name", targetGroup)
+	}
+	if opts != nil && opts.PoolLayout != "" {
+		args = append(args, "--pool_layout", opts.PoolLayout)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to clone subvolume %q snapshot %q to %q in filesystem %q group %q", subvolume, snapshot, targetName, fsName, group)
+	}
+	return nil
+}
+
+// CloneStatus returns the status of an in-flight or completed subvolume clone.
+func CloneStatus(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, cloneName, group string) (*SubVolumeCloneStatus, error) {
+	args := []string{"fs", "clone", "status", fsName, cloneName}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get clone status for %q in filesystem %q group %q", cloneName, fsName, group)
+	}
+
+	var resp subVolumeCloneStatusResponse
+	if err := json.Unmarshal(buf, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal clone status for %q in filesystem %q group %q", cloneName, fsName, group)
+	}
+	return &resp.Status, nil
+}
+
+// CancelClone aborts an in-progress subvolume clone operation.
+func CancelClone(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, cloneName, group string) error {
+	args := []string{"fs", "clone", "cancel", fsName, cloneName}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to cancel clone %q in filesystem %q group %q", cloneName, fsName, group)
+	}
+	return nil
+}
+
+// WaitForCloneComplete polls the clone status until it reports complete, or returns an error if
+// the clone fails, is canceled, or the timeout elapses first.
+func WaitForCloneComplete(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, cloneName, group string, retryInterval, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(clusterInfo.Context, retryInterval, timeout, true, func(c ctx.Context) (bool, error) {
+		status, err := CloneStatus(context, clusterInfo, fsName, cloneName, group)
+		if err != nil {
+			logger.Errorf("failed to get clone status for %q in filesystem %q. %v", cloneName, fsName, err)
+			return false, nil
+		}
+		switch status.State {
+		case CloneStateComplete:
+			return true, nil
+		case CloneStateFailed:
+			return false, errors.Errorf("clone %q in filesystem %q failed: %s", cloneName, fsName, status.FailureReason)
+		case CloneStateCanceled:
+			return false, errors.Errorf("clone %q in filesystem %q was canceled", cloneName, fsName)
+		default:
+			return false, nil
+		}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to wait for clone %q in filesystem %q to complete", cloneName, fsName)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// QuotaSize is the size argument accepted by ResizeSubVolume: either a concrete byte count or the
+// Infinite sentinel that clears the subvolume's quota.
+type QuotaSize interface {
+	resizeValue() string
+}
+
+// ByteCount is a QuotaSize expressed as an absolute number of bytes.
+type ByteCount uint64
+
+func (b ByteCount) resizeValue() string {
+	return strconv.FormatUint(uint64(b), 10)
+}
+
+type infiniteQuotaSize struct{}
+
+func (infiniteQuotaSize) resizeValue() string {
+	return "infinite"
+}
+
+// Infinite is the QuotaSize that removes a subvolume's quota, matching the literal "infinite"
+// accepted by 'ceph fs subvolume resize'.
+var Infinite QuotaSize = infiniteQuotaSize{}
+
+// SubVolumeInfo is a representation of the json structure returned by 'ceph fs subvolume info'.
+type SubVolumeInfo struct {
+	BytesQuota QuotaSize
+	BytesUsed  uint64
+	DataPool   string
+	Path       string
+	Mode       string
+	UID        int
+	GID        int
+	CreatedAt  string
+}
+
+type rawSubVolumeInfo struct {
+	BytesQuota json.RawMessage `json:"bytes_quota"`
+	BytesUsed  uint64          `json:"bytes_used"`
+	DataPool   string          `json:"data_pool"`
+	Path       string          `json:"path"`
+	Mode       string          `json:"mode"`
+	UID        int             `json:"uid"`
+	GID        int             `json:"gid"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// UnmarshalJSON decodes 'bytes_quota' as either a numeric byte count or the literal string
+// "infinite" into the corresponding QuotaSize value.
+func (s *SubVolumeInfo) UnmarshalJSON(data []byte) error {
+	var raw rawSubVolumeInfo
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	quota, err := unmarshalQuotaSize(raw.BytesQuota)
+	if err != nil {
+		return err
+	}
+
+	s.BytesQuota = quota
+	s.BytesUsed = raw.BytesUsed
+	s.DataPool = raw.DataPool
+	s.Path = raw.Path
+	s.Mode = raw.Mode
+	s.UID = raw.UID
+	s.GID = raw.GID
+	s.CreatedAt = raw.CreatedAt
+	return nil
+}
+
+func unmarshalQuotaSize(raw json.RawMessage) (QuotaSize, error) {
+	var asString string
+	if err := json.Unmarshal(raw, &asString); err == nil {
+		if asString != "infinite" {
+			return nil, errors.Errorf("unexpected bytes_quota value %q", asString)
+		}
+		return Infinite, nil
+	}
+
+	var asNumber uint64
+	if err := json.Unmarshal(raw, &asNumber); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal bytes_quota %q", string(raw))
+	}
+	return ByteCount(asNumber), nil
+}
+
+// ResizeSubVolume sets the quota of a CephFS subvolume. Passing Infinite as size clears the quota.
+// If noShrink is true, the resize fails rather than shrinking the quota below the subvolume's
+// current usage.
+func ResizeSubVolume(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, group, subvolume string, size QuotaSize, noShrink bool) error {
+	args := []string{"fs", "subvolume", "resize", fsName, subvolume, size.resizeValue()}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+	if noShrink {
+		args = append(args, "--no_shrink")
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to resize subvolume %q in filesystem %q group %q", subvolume, fsName, group)
+	}
+	return nil
+}
+
+// GetSubVolumeInfo returns detailed information about a CephFS subvolume, including its current
+// quota and usage.
+func GetSubVolumeInfo(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, group, subvolume string) (*SubVolumeInfo, error) {
+	args := []string{"fs", "subvolume", "info", fsName, subvolume}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get subvolume info for %q in filesystem %q group %q", subvolume, fsName, group)
+	}
+
+	var info SubVolumeInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal subvolume info for %q in filesystem %q group %q", subvolume, fsName, group)
+	}
+	return &info, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalQuotaSize(t *testing.T) {
+	quota, err := unmarshalQuotaSize(json.RawMessage(`"infinite"`))
+	assert.NoError(t, err)
+	assert.Equal(t, Infinite, quota)
+
+	quota, err = unmarshalQuotaSize(json.RawMessage(`1073741824`))
+	assert.NoError(t, err)
+	assert.Equal(t, ByteCount(1073741824), quota)
+
+	_, err = unmarshalQuotaSize(json.RawMessage(`"unlimited"`))
+	assert.Error(t, err)
+}
+
+func TestSubVolumeInfoUnmarshalJSON(t *testing.T) {
+	var info SubVolumeInfo
+	err := json.Unmarshal([]byte(`{
+		"bytes_quota": "infinite",
+		"bytes_used": 2048,
+		"data_pool": "myfs-data0",
+		"path": "/volumes/csi/csi-vol-1",
+		"mode": "755",
+		"uid": 0,
+		"gid": 0,
+		"created_at": "2023-01-01 00:00:00"
+	}`), &info)
+	assert.NoError(t, err)
+	assert.Equal(t, Infinite, info.BytesQuota)
+	assert.Equal(t, uint64(2048), info.BytesUsed)
+	assert.Equal(t, "myfs-data0", info.DataPool)
+
+	err = json.Unmarshal([]byte(`{"bytes_quota": 1024}`), &info)
+	assert.NoError(t, err)
+	assert.Equal(t, ByteCount(1024), info.BytesQuota)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// SetSubVolumeSnapshotMetadata sets a single metadata key/value pair on a CephFS subvolume
+// snapshot. Rook uses this to stamp snapshots with provenance such as the owning PVC UID.
+func SetSubVolumeSnapshotMetadata(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, subvolume, snapshot, group, key, value string) error {
+	args := []string{"fs", "subvolume", "snapshot", "metadata", "set", fsName, subvolume, snapshot, key, value}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to set metadata key %q on snapshot %q of subvolume %q in filesystem %q group %q", key, snapshot, subvolume, fsName, group)
+	}
+	return nil
+}
+
+// GetSubVolumeSnapshotMetadata returns the value of a single metadata key on a CephFS subvolume
+// snapshot.
+func GetSubVolumeSnapshotMetadata(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, subvolume, snapshot, group, key string) (string, error) {
+	args := []string{"fs", "subvolume", "snapshot", "metadata", "get", fsName, subvolume, snapshot, key}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get metadata key %q on snapshot %q of subvolume %q in filesystem %q group %q", key, snapshot, subvolume, fsName, group)
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+// RemoveSubVolumeSnapshotMetadata removes a single metadata key from a CephFS subvolume snapshot.
+func RemoveSubVolumeSnapshotMetadata(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, subvolume, snapshot, group, key string) error {
+	args := []string{"fs", "subvolume", "snapshot", "metadata", "rm", fsName, subvolume, snapshot, key}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	if _, err := cmd.RunWithTimeout(exec.CephCommandsTimeout); err != nil {
+		return errors.Wrapf(err, "failed to remove metadata key %q on snapshot %q of subvolume %q in filesystem %q group %q", key, snapshot, subvolume, fsName, group)
+	}
+	return nil
+}
+
+// ListSubVolumeSnapshotMetadata returns all metadata key/value pairs set on a CephFS subvolume
+// snapshot.
+func ListSubVolumeSnapshotMetadata(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, subvolume, snapshot, group string) (map[string]string, error) {
+	args := []string{"fs", "subvolume", "snapshot", "metadata", "ls", fsName, subvolume, snapshot}
+	if group != NoSubvolumeGroup {
+		args = append(args, "--group_name", group)
+	}
+
+	cmd := NewCephCommand(context, clusterInfo, args)
+	buf, err := cmd.RunWithTimeout(exec.CephCommandsTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list metadata on snapshot %q of subvolume %q in filesystem %q group %q", snapshot, subvolume, fsName, group)
+	}
+
+	metadata := map[string]string{}
+	if err := json.Unmarshal(buf, &metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal metadata list for snapshot %q of subvolume %q in filesystem %q group %q", snapshot, subvolume, fsName, group)
+	}
+	return metadata, nil
+}